@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 type AlgorithmRequest struct {
@@ -22,6 +24,13 @@ type SortData struct {
 	J     int   `json:"j"`
 }
 
+type SearchData struct {
+	Array []int `json:"array"`
+	Low   int   `json:"low"`
+	High  int   `json:"high"`
+	Mid   int   `json:"mid"`
+}
+
 func main() {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Algo Visualizer Backend API")
@@ -30,70 +39,139 @@ func main() {
 	http.HandleFunc("/api/sort", handleSort)
 	http.HandleFunc("/api/search", handleSearch)
 	http.HandleFunc("/api/pathfind", handlePathfind)
+	http.HandleFunc("/api/control", handleControl)
 
 	fmt.Println("Server starting on :8080")
 	http.ListenAndServe(":8080", nil)
 }
 
+// sortParams and searchParams pull the algorithm parameters out of either
+// a JSON body (POST, the historical shape) or the query string (GET,
+// needed so a WebSocket upgrade request - which carries no body - can
+// still select an algorithm).
+func sortParams(r *http.Request) (algorithm string, size int, err error) {
+	if r.Method == http.MethodPost {
+		var req AlgorithmRequest
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return
+		}
+		algorithm, _ = req.Params["algorithm"].(string)
+		if s, ok := req.Params["size"].(float64); ok {
+			size = int(s)
+		}
+		return
+	}
+	algorithm = r.URL.Query().Get("algorithm")
+	size, err = strconv.Atoi(r.URL.Query().Get("size"))
+	return
+}
+
+func searchParams(r *http.Request) (algorithm string, size, target int, err error) {
+	if r.Method == http.MethodPost {
+		var req AlgorithmRequest
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return
+		}
+		algorithm, _ = req.Params["algorithm"].(string)
+		if s, ok := req.Params["size"].(float64); ok {
+			size = int(s)
+		}
+		if t, ok := req.Params["target"].(float64); ok {
+			target = int(t)
+		}
+		return
+	}
+	algorithm = r.URL.Query().Get("algorithm")
+	size, _ = strconv.Atoi(r.URL.Query().Get("size"))
+	target, err = strconv.Atoi(r.URL.Query().Get("target"))
+	return
+}
+
+// startRun launches gen in its own goroutine, streaming its steps back on
+// the returned channel, and wires it up to the shared runControl/deadline
+// machinery so pause, resume, step, and cancel all work regardless of
+// whether the caller ends up on SSE or WebSocket.
+func startRun(r *http.Request, gen func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error) (out chan AlgorithmStep, done chan error, ctrl *runControl, timedOut func() bool, runID string) {
+	ctx, timedOut, cancel := deadlineContext(r.Context(), r)
+	ctrl = newRunControl(cancel)
+	runID = registerRun(ctrl)
+
+	out = make(chan AlgorithmStep)
+	done = make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		defer unregisterRun(runID)
+		done <- gen(ctx, out, ctrl)
+	}()
+
+	return out, done, ctrl, timedOut, runID
+}
+
 func handleSort(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req AlgorithmRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	algorithm, size, err := sortParams(r)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	algorithm := req.Params["algorithm"].(string)
-	size := int(req.Params["size"].(float64))
-
-	var steps []AlgorithmStep
-
+	var gen func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error
 	switch algorithm {
 	case "bubble":
-		steps = generateBubbleSort(size)
+		gen = func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error {
+			return generateBubbleSort(ctx, size, out, ctrl)
+		}
 	case "quick":
-		steps = generateQuickSort(size)
+		gen = func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error {
+			return generateQuickSort(ctx, size, out, ctrl)
+		}
 	default:
 		http.Error(w, "Unknown sort algorithm", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(steps)
+	out, done, ctrl, timedOut, runID := startRun(r, gen)
+	if websocketRequested(r) {
+		wsStream(w, r, ctrl, out, done, timedOut)
+		return
+	}
+	sseWriter(w, runID, out, done, timedOut)
 }
 
 func handleSearch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req AlgorithmRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	algorithm, size, target, err := searchParams(r)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	algorithm := req.Params["algorithm"].(string)
-	size := int(req.Params["size"].(float64))
-	target := int(req.Params["target"].(float64))
-
-	var steps []AlgorithmStep
-
+	var gen func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error
 	switch algorithm {
 	case "binary":
-		steps = generateBinarySearch(size, target)
+		gen = func(ctx context.Context, out chan<- AlgorithmStep, ctrl *runControl) error {
+			return generateBinarySearch(ctx, size, target, out, ctrl)
+		}
 	default:
 		http.Error(w, "Unknown search algorithm", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(steps)
+	out, done, ctrl, timedOut, runID := startRun(r, gen)
+	if websocketRequested(r) {
+		wsStream(w, r, ctrl, out, done, timedOut)
+		return
+	}
+	sseWriter(w, runID, out, done, timedOut)
 }
 
 func handlePathfind(w http.ResponseWriter, r *http.Request) {
@@ -102,67 +180,120 @@ func handlePathfind(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode([]AlgorithmStep{{Step: 1, Data: "Pathfinding not implemented yet"}})
 }
 
-func generateBubbleSort(size int) []AlgorithmStep {
+func websocketRequested(r *http.Request) bool {
+	return r.Header.Get("Upgrade") == "websocket"
+}
+
+func generateBubbleSort(ctx context.Context, size int, out chan<- AlgorithmStep, ctrl *runControl) error {
 	arr := make([]int, size)
 	for i := range arr {
 		arr[i] = size - i
 	}
 
-	steps := []AlgorithmStep{}
 	step := 0
-
 	for i := 0; i < len(arr); i++ {
 		for j := 0; j < len(arr)-1-i; j++ {
+			if err := ctrl.gate(ctx); err != nil {
+				return err
+			}
 			step++
-			steps = append(steps, AlgorithmStep{
+			if err := send(ctx, out, AlgorithmStep{
 				Step: step,
 				Data: SortData{Array: append([]int(nil), arr...), I: i, J: j},
-			})
+			}); err != nil {
+				return err
+			}
 			if arr[j] > arr[j+1] {
 				arr[j], arr[j+1] = arr[j+1], arr[j]
 			}
 		}
 	}
 
-	return steps
+	return nil
 }
 
-func generateQuickSort(size int) []AlgorithmStep {
+func generateQuickSort(ctx context.Context, size int, out chan<- AlgorithmStep, ctrl *runControl) error {
 	arr := make([]int, size)
 	for i := range arr {
 		arr[i] = size - i
 	}
 
-	steps := []AlgorithmStep{}
 	step := 0
-
-	quickSort(arr, 0, len(arr)-1, &steps, &step)
-	return steps
+	return quickSort(ctx, arr, 0, len(arr)-1, out, &step, ctrl)
 }
 
-func quickSort(arr []int, low, high int, steps *[]AlgorithmStep, step *int) {
+func quickSort(ctx context.Context, arr []int, low, high int, out chan<- AlgorithmStep, step *int, ctrl *runControl) error {
 	if low < high {
-		pivotIndex := partition(arr, low, high, steps, step)
-		quickSort(arr, low, pivotIndex-1, steps, step)
-		quickSort(arr, pivotIndex+1, high, steps, step)
+		pivotIndex, err := partition(ctx, arr, low, high, out, step, ctrl)
+		if err != nil {
+			return err
+		}
+		if err := quickSort(ctx, arr, low, pivotIndex-1, out, step, ctrl); err != nil {
+			return err
+		}
+		if err := quickSort(ctx, arr, pivotIndex+1, high, out, step, ctrl); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func partition(arr []int, low, high int, steps *[]AlgorithmStep, step *int) int {
+func partition(ctx context.Context, arr []int, low, high int, out chan<- AlgorithmStep, step *int, ctrl *runControl) (int, error) {
 	pivot := arr[high]
 	i := low - 1
 
 	for j := low; j < high; j++ {
+		if err := ctrl.gate(ctx); err != nil {
+			return 0, err
+		}
 		*step++
-		*steps = append(*steps, AlgorithmStep{
+		if err := send(ctx, out, AlgorithmStep{
 			Step: *step,
 			Data: SortData{Array: append([]int(nil), arr...), I: i, J: j},
-		})
+		}); err != nil {
+			return 0, err
+		}
 		if arr[j] < pivot {
 			i++
 			arr[i], arr[j] = arr[j], arr[i]
 		}
 	}
 	arr[i+1], arr[high] = arr[high], arr[i+1]
-	return i + 1
+	return i + 1, nil
+}
+
+// generateBinarySearch walks arr looking for target, emitting the
+// [low, high, mid] window at each step so the frontend can animate the
+// search converging.
+func generateBinarySearch(ctx context.Context, size, target int, out chan<- AlgorithmStep, ctrl *runControl) error {
+	arr := make([]int, size)
+	for i := range arr {
+		arr[i] = i
+	}
+
+	step := 0
+	low, high := 0, len(arr)-1
+	for low <= high {
+		if err := ctrl.gate(ctx); err != nil {
+			return err
+		}
+		mid := low + (high-low)/2
+		step++
+		if err := send(ctx, out, AlgorithmStep{
+			Step: step,
+			Data: SearchData{Array: arr, Low: low, High: high, Mid: mid},
+		}); err != nil {
+			return err
+		}
+		switch {
+		case arr[mid] == target:
+			return nil
+		case arr[mid] < target:
+			low = mid + 1
+		default:
+			high = mid - 1
+		}
+	}
+
+	return nil
 }
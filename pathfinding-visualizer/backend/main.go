@@ -1,8 +1,11 @@
 package main
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"log"
+	"math/big"
+	mathrand "math/rand"
 	"net/http"
 	"strconv"
 )
@@ -13,13 +16,32 @@ type Point struct {
 
 type MazeRequest struct {
 	Width, Height int
+	Seed          int64
+	Algorithm     string
 }
 
+// MazeResponse echoes the seed and algorithm actually used alongside the
+// grid, so a client can replay the exact same maze later via
+// /maze?seed=...&algo=....
+type MazeResponse struct {
+	Grid      [][]int `json:"grid"`
+	Seed      int64   `json:"seed"`
+	Algorithm string  `json:"algorithm"`
+}
+
+// PathRequest's Maze cells carry an integer traversal cost rather than a
+// plain 0/1 wall flag: 1 is a wall - the same value every /maze generator
+// emits for walls - and any value >= 2 (2 = mud, 5 = water, ...) weights
+// that cell's step cost; 0 is kept as the default cost of 1, matching the
+// pre-weight grids where 0 meant open path. Movement selects
+// 4-directional, 8-directional, or 8-directional movement that refuses to
+// cut across a wall corner.
 type PathRequest struct {
-	Maze  [][]int `json:"maze"`
-	Start Point   `json:"start"`
-	End   Point   `json:"end"`
-	Algo  string  `json:"algo"` // "astar" or "bfs"
+	Maze     [][]int  `json:"maze"`
+	Start    Point    `json:"start"`
+	End      Point    `json:"end"`
+	Algo     string   `json:"algo"`
+	Movement Movement `json:"movement"`
 }
 
 type PathResponse struct {
@@ -29,17 +51,20 @@ type PathResponse struct {
 func main() {
 	http.HandleFunc("/maze", handleMaze)
 	http.HandleFunc("/path", handlePath)
+	http.HandleFunc("/api/control", handleControl)
 	http.Handle("/", http.FileServer(http.Dir("../frontend")))
 
 	log.Println("Server starting on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
 
+// handleMaze accepts either the long-standing width/height query params
+// or the shorthand w/h form, plus optional seed and algo params so a
+// maze can be replayed byte-for-byte: /maze?seed=12345&algo=prims&w=40&h=40.
 func handleMaze(w http.ResponseWriter, r *http.Request) {
-	widthStr := r.URL.Query().Get("width")
-	heightStr := r.URL.Query().Get("height")
-	width, _ := strconv.Atoi(widthStr)
-	height, _ := strconv.Atoi(heightStr)
+	query := r.URL.Query()
+	width, _ := strconv.Atoi(firstNonEmpty(query.Get("width"), query.Get("w")))
+	height, _ := strconv.Atoi(firstNonEmpty(query.Get("height"), query.Get("h")))
 	if width == 0 {
 		width = 20
 	}
@@ -47,23 +72,68 @@ func handleMaze(w http.ResponseWriter, r *http.Request) {
 		height = 20
 	}
 
-	maze := generateMaze(width, height)
+	seed := int64(0)
+	if seedStr := query.Get("seed"); seedStr != "" {
+		seed, _ = strconv.ParseInt(seedStr, 10, 64)
+	} else {
+		seed = randomSeed()
+	}
+
+	generator, algo := mazeGenerator(query.Get("algo"))
+	maze := generator.Generate(width, height, mathrand.New(mathrand.NewSource(seed)))
+
+	resp := MazeResponse{Grid: maze, Seed: seed, Algorithm: algo}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(maze)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
+// randomSeed picks an unpredictable seed for requests that don't pin one,
+// falling back to a fixed seed only if the system CSPRNG is unavailable.
+func randomSeed() int64 {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return 1
+	}
+	return n.Int64()
+}
+
+// handlePath streams each node the search visits as it happens, ending -
+// when a route is found - with a "path" step carrying the reconstructed
+// route (a search that finds no route sends no "path" step), over SSE by
+// default or a WebSocket when the client asks to upgrade. A per-request
+// deadline (?deadline=30s) and pause/resume/step/cancel control messages
+// are honoured the same way as the algo-visualizer streams.
 func handlePath(w http.ResponseWriter, r *http.Request) {
 	var req PathRequest
 	json.NewDecoder(r.Body).Decode(&req)
 
-	var path []Point
-	if req.Algo == "astar" {
-		path = astar(req.Maze, req.Start, req.End)
-	} else {
-		path = bfs(req.Maze, req.Start, req.End)
-	}
+	ctx, timedOut, cancel := deadlineContext(r.Context(), r)
+	ctrl := newRunControl(cancel)
+	runID := registerRun(ctrl)
 
-	resp := PathResponse{Path: path}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	out := make(chan AlgorithmStep)
+	done := make(chan error, 1)
+
+	search, _ := pathAlgorithm(req.Algo)
+	go func() {
+		defer close(out)
+		defer unregisterRun(runID)
+		_, err := search(ctx, req.Maze, req.Start, req.End, req.Movement, out, ctrl)
+		done <- err
+	}()
+
+	if websocketRequested(r) {
+		wsStream(w, r, ctrl, out, done, timedOut)
+		return
+	}
+	sseWriter(w, runID, out, done, timedOut)
 }
@@ -4,34 +4,451 @@ import (
 	"math/rand"
 )
 
-func generateMaze(width, height int) [][]int {
-	maze := make([][]int, height)
-	for i := range maze {
-		maze[i] = make([]int, width)
-		for j := range maze[i] {
-			maze[i][j] = 1 // wall
+// MazeGenerator carves a width x height grid (1 = wall, 0 = path) using a
+// caller-supplied random source, so for any one algorithm the same seed
+// produces the same maze every time.
+type MazeGenerator interface {
+	Generate(width, height int, rng *rand.Rand) [][]int
+}
+
+var mazeGenerators = map[string]MazeGenerator{
+	"backtracker": recursiveBacktracker{},
+	"prims":       prims{},
+	"kruskals":    kruskals{},
+	"wilsons":     wilsons{},
+	"ellers":      ellers{},
+	"division":    recursiveDivision{},
+}
+
+const defaultMazeAlgorithm = "backtracker"
+
+func mazeGenerator(algo string) (MazeGenerator, string) {
+	if gen, ok := mazeGenerators[algo]; ok {
+		return gen, algo
+	}
+	return mazeGenerators[defaultMazeAlgorithm], defaultMazeAlgorithm
+}
+
+// cellPoint is a logical maze cell; two cells are 2 grid units apart, with
+// the wall (or opening) between them at their midpoint.
+type cellPoint struct{ x, y int }
+
+// gridDims returns the number of logical cells a width x height grid has
+// room for, following the odd-coordinate convention used throughout this
+// file: room cells sit at (2x+1, 2y+1).
+func gridDims(width, height int) (cols, rows int) {
+	return (width - 1) / 2, (height - 1) / 2
+}
+
+// newRoomGrid allocates a grid of walls with every room cell already
+// carved out; algorithms only need to decide which walls between
+// neighbouring rooms to knock down.
+func newRoomGrid(width, height int) (grid [][]int, cols, rows int) {
+	grid = make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+		for x := range grid[y] {
+			grid[y][x] = 1
+		}
+	}
+	cols, rows = gridDims(width, height)
+	for cy := 0; cy < rows; cy++ {
+		for cx := 0; cx < cols; cx++ {
+			grid[2*cy+1][2*cx+1] = 0
+		}
+	}
+	return grid, cols, rows
+}
+
+func cellIndex(cx, cy, cols int) int { return cy*cols + cx }
+
+func cellNeighbours(cx, cy, cols, rows int) []cellPoint {
+	candidates := []cellPoint{{cx + 1, cy}, {cx - 1, cy}, {cx, cy + 1}, {cx, cy - 1}}
+	neighbours := make([]cellPoint, 0, 4)
+	for _, c := range candidates {
+		if c.x >= 0 && c.x < cols && c.y >= 0 && c.y < rows {
+			neighbours = append(neighbours, c)
+		}
+	}
+	return neighbours
+}
+
+// openWall knocks down the wall between two adjacent logical cells.
+func openWall(grid [][]int, ax, ay, bx, by int) {
+	x1, y1 := 2*ax+1, 2*ay+1
+	x2, y2 := 2*bx+1, 2*by+1
+	grid[(y1+y2)/2][(x1+x2)/2] = 0
+}
+
+// recursiveBacktracker is the original algorithm this package shipped
+// with, now driven by an injected rng and expressed over logical cells
+// instead of raw pixel coordinates.
+type recursiveBacktracker struct{}
+
+func (recursiveBacktracker) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid, cols, rows := newRoomGrid(width, height)
+	if cols == 0 || rows == 0 {
+		return grid
+	}
+
+	visited := make([]bool, cols*rows)
+	stack := []cellPoint{{0, 0}}
+	visited[0] = true
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		neighbours := cellNeighbours(current.x, current.y, cols, rows)
+		rng.Shuffle(len(neighbours), func(i, j int) {
+			neighbours[i], neighbours[j] = neighbours[j], neighbours[i]
+		})
+
+		advanced := false
+		for _, n := range neighbours {
+			idx := cellIndex(n.x, n.y, cols)
+			if !visited[idx] {
+				visited[idx] = true
+				openWall(grid, current.x, current.y, n.x, n.y)
+				stack = append(stack, n)
+				advanced = true
+				break
+			}
 		}
+		if !advanced {
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return grid
+}
+
+// prims grows the maze from a single cell, at each step connecting a
+// random frontier cell to the tree it is adjacent to.
+type prims struct{}
+
+type mazeEdge struct{ from, to cellPoint }
+
+func (prims) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid, cols, rows := newRoomGrid(width, height)
+	if cols == 0 || rows == 0 {
+		return grid
 	}
 
-	// Start from top-left
-	carve(maze, 1, 1)
+	inMaze := make([]bool, cols*rows)
+	inMaze[0] = true
 
-	return maze
+	var frontier []mazeEdge
+	addFrontier := func(c cellPoint) {
+		for _, n := range cellNeighbours(c.x, c.y, cols, rows) {
+			if !inMaze[cellIndex(n.x, n.y, cols)] {
+				frontier = append(frontier, mazeEdge{from: c, to: n})
+			}
+		}
+	}
+	addFrontier(cellPoint{0, 0})
+
+	for len(frontier) > 0 {
+		i := rng.Intn(len(frontier))
+		edge := frontier[i]
+		frontier = append(frontier[:i], frontier[i+1:]...)
+
+		if inMaze[cellIndex(edge.to.x, edge.to.y, cols)] {
+			continue
+		}
+		inMaze[cellIndex(edge.to.x, edge.to.y, cols)] = true
+		openWall(grid, edge.from.x, edge.from.y, edge.to.x, edge.to.y)
+		addFrontier(edge.to)
+	}
+
+	return grid
 }
 
-func carve(maze [][]int, x, y int) {
-	directions := [][]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
-	rand.Shuffle(len(directions), func(i, j int) {
-		directions[i], directions[j] = directions[j], directions[i]
-	})
+// kruskals joins cells in random edge order, using union-find to reject
+// any edge that would close a loop.
+type kruskals struct{}
 
-	maze[y][x] = 0 // path
+type unionFind struct{ parent []int }
 
-	for _, dir := range directions {
-		nx, ny := x+dir[0]*2, y+dir[1]*2
-		if nx > 0 && nx < len(maze[0])-1 && ny > 0 && ny < len(maze)-1 && maze[ny][nx] == 1 {
-			maze[y+dir[1]][x+dir[0]] = 0
-			carve(maze, nx, ny)
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b int) {
+	u.parent[u.find(a)] = u.find(b)
+}
+
+func (kruskals) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid, cols, rows := newRoomGrid(width, height)
+	if cols == 0 || rows == 0 {
+		return grid
+	}
+
+	var edges []mazeEdge
+	for cy := 0; cy < rows; cy++ {
+		for cx := 0; cx < cols; cx++ {
+			if cx+1 < cols {
+				edges = append(edges, mazeEdge{from: cellPoint{cx, cy}, to: cellPoint{cx + 1, cy}})
+			}
+			if cy+1 < rows {
+				edges = append(edges, mazeEdge{from: cellPoint{cx, cy}, to: cellPoint{cx, cy + 1}})
+			}
 		}
 	}
+	rng.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+
+	uf := newUnionFind(cols * rows)
+	for _, edge := range edges {
+		a := cellIndex(edge.from.x, edge.from.y, cols)
+		b := cellIndex(edge.to.x, edge.to.y, cols)
+		if uf.find(a) != uf.find(b) {
+			uf.union(a, b)
+			openWall(grid, edge.from.x, edge.from.y, edge.to.x, edge.to.y)
+		}
+	}
+
+	return grid
+}
+
+// wilsons builds a uniform spanning tree via loop-erased random walks:
+// each unvisited cell walks randomly, erasing any loop it forms, until it
+// hits the growing maze, then the whole walk is carved in.
+type wilsons struct{}
+
+func (wilsons) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid, cols, rows := newRoomGrid(width, height)
+	total := cols * rows
+	if total == 0 {
+		return grid
+	}
+
+	inMaze := make([]bool, total)
+	inMaze[rng.Intn(total)] = true
+	remaining := total - 1
+
+	for remaining > 0 {
+		start := rng.Intn(total)
+		if inMaze[start] {
+			continue
+		}
+
+		walk := []int{start}
+		position := map[int]int{start: 0}
+		current := start
+		for !inMaze[current] {
+			cx, cy := current%cols, current/cols
+			neighbours := cellNeighbours(cx, cy, cols, rows)
+			next := neighbours[rng.Intn(len(neighbours))]
+			nextIdx := cellIndex(next.x, next.y, cols)
+
+			if loopStart, ok := position[nextIdx]; ok {
+				walk = walk[:loopStart+1]
+				for idx, pos := range position {
+					if pos > loopStart {
+						delete(position, idx)
+					}
+				}
+			} else {
+				position[nextIdx] = len(walk)
+				walk = append(walk, nextIdx)
+			}
+			current = nextIdx
+		}
+
+		for i := 0; i < len(walk)-1; i++ {
+			a, b := walk[i], walk[i+1]
+			if !inMaze[a] {
+				inMaze[a] = true
+				remaining--
+			}
+			openWall(grid, a%cols, a/cols, b%cols, b/cols)
+		}
+	}
+
+	return grid
+}
+
+// ellers processes the maze one row at a time, tracking which set each
+// cell belongs to, joining cells horizontally at random and dropping at
+// least one vertical connection per set before moving to the next row.
+type ellers struct{}
+
+func (ellers) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid, cols, rows := newRoomGrid(width, height)
+	if cols == 0 || rows == 0 {
+		return grid
+	}
+
+	sets := make([]int, cols)
+	nextSet := 0
+	for cx := range sets {
+		sets[cx] = nextSet
+		nextSet++
+	}
+
+	for cy := 0; cy < rows; cy++ {
+		lastRow := cy == rows-1
+
+		for cx := 0; cx < cols-1; cx++ {
+			if sets[cx] == sets[cx+1] {
+				continue
+			}
+			if lastRow || rng.Intn(2) == 0 {
+				openWall(grid, cx, cy, cx+1, cy)
+				old, replacement := sets[cx+1], sets[cx]
+				for k := range sets {
+					if sets[k] == old {
+						sets[k] = replacement
+					}
+				}
+			}
+		}
+
+		if lastRow {
+			break
+		}
+
+		// Group columns by set in a fixed order (map iteration order is
+		// randomised in Go and would make this non-deterministic for a
+		// given seed).
+		var setOrder []int
+		bySet := make(map[int][]int)
+		for cx, s := range sets {
+			if _, ok := bySet[s]; !ok {
+				setOrder = append(setOrder, s)
+			}
+			bySet[s] = append(bySet[s], cx)
+		}
+
+		nextRowSets := make([]int, cols)
+		for i := range nextRowSets {
+			nextRowSets[i] = -1
+		}
+		for _, s := range setOrder {
+			members := bySet[s]
+			rng.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+			connections := 1 + rng.Intn(len(members))
+			for i := 0; i < connections; i++ {
+				cx := members[i]
+				openWall(grid, cx, cy, cx, cy+1)
+				nextRowSets[cx] = s
+			}
+		}
+		for cx := range nextRowSets {
+			if nextRowSets[cx] == -1 {
+				nextRowSets[cx] = nextSet
+				nextSet++
+			}
+		}
+		sets = nextRowSets
+	}
+
+	return grid
+}
+
+// recursiveDivision starts from an entirely open chamber and repeatedly
+// bisects it with a wall carrying a single passage, unlike the other
+// generators which grow corridors from a fully-walled grid.
+type recursiveDivision struct{}
+
+func (recursiveDivision) Generate(width, height int, rng *rand.Rand) [][]int {
+	grid := make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+	}
+	for x := 0; x < width; x++ {
+		grid[0][x] = 1
+		grid[height-1][x] = 1
+	}
+	for y := 0; y < height; y++ {
+		grid[y][0] = 1
+		grid[y][width-1] = 1
+	}
+
+	// On an even width/height, gridDims floors down to the last full room
+	// column/row, leaving one grid column/row between that room and the
+	// border wall above that belongs to no logical cell. divideCells never
+	// draws a seam there, so left unwalled it reads as a permanently open
+	// corridor along that edge. Seal it the same as the border.
+	cols, rows := gridDims(width, height)
+	if sealX := 2 * cols; sealX < width-1 {
+		for y := 0; y < height; y++ {
+			grid[y][sealX] = 1
+		}
+	}
+	if sealY := 2 * rows; sealY < height-1 {
+		for x := 0; x < width; x++ {
+			grid[sealY][x] = 1
+		}
+	}
+
+	divideCells(grid, 0, 0, cols, rows, rng)
+	return grid
+}
+
+// divideCells recursively splits a cellCols x cellRows block of logical
+// cells (the same odd-coordinate room convention gridDims/newRoomGrid use
+// elsewhere in this file) with a wall along the seam between two cell
+// rows or columns, leaving a single passage through one room cell. An
+// earlier, pixel-based version of this function picked its wall and
+// passage coordinates directly in grid space; a nested split could then
+// land its own wall exactly on the cell a parent split's passage opened
+// into, sealing the passage shut from one side and isolating it. Working
+// in cell units instead makes that impossible: every wall this function
+// draws sits on an even (seam) row or column, every passage sits on an
+// odd (room) row and column, and recursion only ever hands a strictly
+// smaller, disjoint cell range down - so no split at any depth can ever
+// touch a cell another split already turned into a passage's endpoint.
+func divideCells(grid [][]int, cellX, cellY, cellCols, cellRows int, rng *rand.Rand) {
+	canSplitHorizontal := cellRows >= 2
+	canSplitVertical := cellCols >= 2
+	if !canSplitHorizontal && !canSplitVertical {
+		return
+	}
+
+	horizontal := canSplitHorizontal && (!canSplitVertical || cellCols < cellRows || (cellCols == cellRows && rng.Intn(2) == 0))
+
+	if horizontal {
+		splitRow := cellY + 1 + randIntn(rng, cellRows-1)
+		wallY := 2 * splitRow
+		passageX := 2*(cellX+randIntn(rng, cellCols)) + 1
+		for px := 2*cellX + 1; px <= 2*(cellX+cellCols-1)+1; px++ {
+			if px != passageX {
+				grid[wallY][px] = 1
+			}
+		}
+		divideCells(grid, cellX, cellY, cellCols, splitRow-cellY, rng)
+		divideCells(grid, cellX, splitRow, cellCols, cellY+cellRows-splitRow, rng)
+		return
+	}
+
+	splitCol := cellX + 1 + randIntn(rng, cellCols-1)
+	wallX := 2 * splitCol
+	passageY := 2*(cellY+randIntn(rng, cellRows)) + 1
+	for py := 2*cellY + 1; py <= 2*(cellY+cellRows-1)+1; py++ {
+		if py != passageY {
+			grid[py][wallX] = 1
+		}
+	}
+	divideCells(grid, cellX, cellY, splitCol-cellX, cellRows, rng)
+	divideCells(grid, splitCol, cellY, cellX+cellCols-splitCol, cellRows, rng)
+}
+
+// randIntn behaves like rng.Intn(n) but tolerates n <= 0, which happens
+// at the edges of a chamber being divided.
+func randIntn(rng *rand.Rand, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return rng.Intn(n)
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestMazeGeneratorsAreDeterministic checks that each algorithm produces
+// the same grid twice for a fixed seed and size, so a regression that
+// reintroduces unseeded/global rand usage gets caught immediately. It does
+// not compare the grids against fixed expected values; only the
+// backtracker's grid is pinned, by TestRecursiveBacktrackerMatchesGoldenGrid.
+func TestMazeGeneratorsAreDeterministic(t *testing.T) {
+	const width, height, seed = 11, 9, 12345
+
+	for name, gen := range mazeGenerators {
+		gen := gen
+		t.Run(name, func(t *testing.T) {
+			first := gen.Generate(width, height, rand.New(rand.NewSource(seed)))
+			second := gen.Generate(width, height, rand.New(rand.NewSource(seed)))
+
+			if !reflect.DeepEqual(first, second) {
+				t.Fatalf("%s: same seed produced different mazes", name)
+			}
+			if len(first) != height || len(first[0]) != width {
+				t.Fatalf("%s: expected %dx%d grid, got %dx%d", name, width, height, len(first[0]), len(first))
+			}
+		})
+	}
+}
+
+// TestRecursiveBacktrackerMatchesGoldenGrid pins the exact grid the
+// backtracker (the only algorithm this package shipped with before
+// chunk0-2) produces for a fixed seed, so a change to its carving order
+// is caught even if it would still happen to be fully connected.
+func TestRecursiveBacktrackerMatchesGoldenGrid(t *testing.T) {
+	golden := [][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 0, 1, 0, 1, 0, 1},
+		{1, 0, 0, 0, 1, 0, 1, 0, 1, 0, 1},
+		{1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+		{1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+		{1, 0, 1, 0, 1, 0, 1, 1, 1, 0, 1},
+		{1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+
+	got := recursiveBacktracker{}.Generate(11, 9, rand.New(rand.NewSource(12345)))
+	if !reflect.DeepEqual(got, golden) {
+		t.Fatalf("backtracker(seed=12345) grid changed:\ngot:    %v\nwanted: %v", got, golden)
+	}
+}
+
+// TestMazeGeneratorsAreFullyConnected floods out from the first open cell
+// and checks every open cell was reached. This is the check that would
+// have caught the recursiveDivision bug where a wall split with a
+// zero-size partition carved a passage into nothing and could isolate a
+// cell: the generators were deterministic and passed
+// TestMazeGeneratorsAreDeterministic while still producing unsolvable
+// mazes.
+func TestMazeGeneratorsAreFullyConnected(t *testing.T) {
+	sizes := []struct{ width, height int }{
+		{21, 15},
+		{11, 9},
+		{8, 8},
+	}
+
+	for name, gen := range mazeGenerators {
+		gen := gen
+		t.Run(name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				for _, size := range sizes {
+					grid := gen.Generate(size.width, size.height, rand.New(rand.NewSource(seed)))
+					if reached, total := floodFillOpenCells(grid); reached != total {
+						t.Fatalf("seed=%d %dx%d: only %d/%d open cells reachable from each other", seed, size.width, size.height, reached, total)
+					}
+				}
+			}
+		})
+	}
+}
+
+// floodFillOpenCells returns how many of the grid's open (0) cells are
+// reachable from an arbitrary open cell via 4-connected moves, alongside
+// the total number of open cells; a fully connected maze has equal
+// values.
+func floodFillOpenCells(grid [][]int) (reached, total int) {
+	var start Point
+	found := false
+	for y, row := range grid {
+		for x, v := range row {
+			if v != 0 {
+				continue
+			}
+			total++
+			if !found {
+				start = Point{x, y}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return 0, 0
+	}
+
+	seen := map[Point]bool{start: true}
+	queue := []Point{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		reached++
+		for _, dir := range directions4 {
+			n := Point{current.X + dir[0], current.Y + dir[1]}
+			if !inBounds(grid, n) || grid[n.Y][n.X] != 0 || seen[n] {
+				continue
+			}
+			seen[n] = true
+			queue = append(queue, n)
+		}
+	}
+	return reached, total
+}
+
+// TestRecursiveDivisionIsPerfectOnEvenDimensions guards against the seam
+// recursiveDivision used to leave unwalled on an even width/height (e.g.
+// the 20x20 /maze default): gridDims floors to the last full room
+// column/row, and the grid column/row just past it - between that room
+// and the border - belongs to no logical cell, so a generator that only
+// ever walls seams between rooms never touches it and it reads as a
+// permanently open corridor. Every opened wall leaves its own midpoint
+// cell open (see openWall), so a perfect (tree-shaped) maze over
+// cols*rows cells has exactly cols*rows-1 passages and 2*(cols*rows-1)
+// open edges - one on each side of every passage cell.
+// TestMazeGeneratorsAreFullyConnected alone can't catch a missing seal
+// because the extra corridor is still connected to everything else.
+func TestRecursiveDivisionIsPerfectOnEvenDimensions(t *testing.T) {
+	const width, height = 20, 20
+	cols, rows := gridDims(width, height)
+	wantEdges := 2 * (cols*rows - 1)
+
+	for seed := int64(0); seed < 10; seed++ {
+		grid := recursiveDivision{}.Generate(width, height, rand.New(rand.NewSource(seed)))
+		if reached, total := floodFillOpenCells(grid); reached != total {
+			t.Fatalf("seed=%d: only %d/%d open cells reachable", seed, reached, total)
+		}
+		if edges := countOpenEdges(grid); edges != wantEdges {
+			t.Fatalf("seed=%d: expected a perfect maze (%d edges for %d cells), got %d edges", seed, wantEdges, cols*rows, edges)
+		}
+	}
+}
+
+// countOpenEdges counts adjacent (4-connected) pairs of open cells,
+// counting each pair once. A perfect (tree-shaped) maze has exactly
+// 2*(cells-1) such edges; any more means a loop.
+func countOpenEdges(grid [][]int) int {
+	edges := 0
+	for y, row := range grid {
+		for x, v := range row {
+			if v != 0 {
+				continue
+			}
+			if x+1 < len(row) && grid[y][x+1] == 0 {
+				edges++
+			}
+			if y+1 < len(grid) && grid[y+1][x] == 0 {
+				edges++
+			}
+		}
+	}
+	return edges
+}
+
+func TestMazeGeneratorUnknownAlgorithmFallsBackToDefault(t *testing.T) {
+	_, algo := mazeGenerator("not-a-real-algorithm")
+	if algo != defaultMazeAlgorithm {
+		t.Fatalf("expected fallback to %q, got %q", defaultMazeAlgorithm, algo)
+	}
+}
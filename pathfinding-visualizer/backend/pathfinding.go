@@ -2,9 +2,176 @@ package main
 
 import (
 	"container/heap"
-	"math"
+	"container/list"
+	"context"
+	"sort"
 )
 
+// Movement selects which neighbours a search is allowed to step to.
+type Movement string
+
+const (
+	Movement4Way            Movement = "4way"
+	Movement8Way            Movement = "8way"
+	Movement8WayNoCornerCut Movement = "8way-nocorner-cut"
+)
+
+var directions4 = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+var directions8 = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// Grid cells hold an integer traversal cost; 1 is a wall - the same value
+// every maze generator in maze.go emits for walls - and anything <= 0
+// otherwise (the historical 0 = "open path" value) is treated as the
+// default cost of 1, so old binary grids keep working.
+const wallCost = 1
+
+func inBounds(grid [][]int, p Point) bool {
+	return p.Y >= 0 && p.Y < len(grid) && p.X >= 0 && p.X < len(grid[0])
+}
+
+func passable(grid [][]int, p Point) bool {
+	return inBounds(grid, p) && grid[p.Y][p.X] != wallCost
+}
+
+func terrainCost(grid [][]int, p Point) int {
+	v := grid[p.Y][p.X]
+	if v <= 0 {
+		return 1
+	}
+	return v
+}
+
+// Step costs are scaled by 10 (orthogonal) / 14 (diagonal, ~10*sqrt2) so
+// diagonal movement stays an integer approximation of true distance.
+const orthogonalStep = 10
+const diagonalStep = 14
+
+type neighbourStep struct {
+	point Point
+	cost  int
+}
+
+// neighbourSteps lists the passable cells reachable from p in one move
+// under movement, each tagged with its terrain-weighted step cost.
+// 8way-nocorner-cut additionally refuses a diagonal move when either of
+// the orthogonal cells it would cut across is a wall.
+func neighbourSteps(grid [][]int, p Point, movement Movement) []neighbourStep {
+	dirs := directions4
+	if movement == Movement8Way || movement == Movement8WayNoCornerCut {
+		dirs = directions8
+	}
+
+	steps := make([]neighbourStep, 0, len(dirs))
+	for _, dir := range dirs {
+		n := Point{p.X + dir[0], p.Y + dir[1]}
+		if !passable(grid, n) {
+			continue
+		}
+
+		diagonal := dir[0] != 0 && dir[1] != 0
+		if diagonal && movement == Movement8WayNoCornerCut {
+			if !passable(grid, Point{p.X + dir[0], p.Y}) || !passable(grid, Point{p.X, p.Y + dir[1]}) {
+				continue
+			}
+		}
+
+		step := orthogonalStep
+		if diagonal {
+			step = diagonalStep
+		}
+		steps = append(steps, neighbourStep{point: n, cost: step * terrainCost(grid, n)})
+	}
+	return steps
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// heuristicFor picks Manhattan distance for 4-way movement and octile
+// distance for 8-way movement, both scaled to match the 10/14 step
+// costs above so the heuristic stays admissible.
+func heuristicFor(movement Movement) func(a, b Point) int {
+	if movement == Movement8Way || movement == Movement8WayNoCornerCut {
+		return func(a, b Point) int {
+			dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+			if dx > dy {
+				return diagonalStep*dy + orthogonalStep*(dx-dy)
+			}
+			return diagonalStep*dx + orthogonalStep*(dy-dx)
+		}
+	}
+	return func(a, b Point) int {
+		return orthogonalStep * (abs(a.X-b.X) + abs(a.Y-b.Y))
+	}
+}
+
+// CellScore pairs a cell with the gScore a search settled on for it; used
+// instead of a map[Point]int because Point isn't a valid JSON map key.
+type CellScore struct {
+	Point  Point `json:"point"`
+	GScore int   `json:"g_score"`
+}
+
+// PathStepData describes one increment of a search: either a node being
+// expanded ("visit") or, once the search finishes, the reconstructed
+// route plus the full exploration order and gScore table ("path").
+type PathStepData struct {
+	Kind     string      `json:"kind"`
+	Point    Point       `json:"point,omitempty"`
+	Path     []Point     `json:"path,omitempty"`
+	Explored []Point     `json:"explored,omitempty"`
+	Scores   []CellScore `json:"scores,omitempty"`
+}
+
+func sortedScores(gScore map[Point]int) []CellScore {
+	scores := make([]CellScore, 0, len(gScore))
+	for p, g := range gScore {
+		scores = append(scores, CellScore{Point: p, GScore: g})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Point.Y != scores[j].Point.Y {
+			return scores[i].Point.Y < scores[j].Point.Y
+		}
+		return scores[i].Point.X < scores[j].Point.X
+	})
+	return scores
+}
+
+func finalStep(step *int, path, explored []Point, gScore map[Point]int) AlgorithmStep {
+	*step++
+	return AlgorithmStep{
+		Step: *step,
+		Data: PathStepData{Kind: "path", Path: path, Explored: explored, Scores: sortedScores(gScore)},
+	}
+}
+
+// PathAlgorithm is the shape every entry in pathAlgorithms implements, so
+// handlePath can dispatch on the Algo discriminator the same way
+// handleMaze dispatches on MazeRequest.Algorithm.
+type PathAlgorithm func(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error)
+
+var pathAlgorithms = map[string]PathAlgorithm{
+	"astar":         astar,
+	"dijkstra":      dijkstra,
+	"greedy":        greedyBestFirst,
+	"bfs":           bfs,
+	"bidirectional": bidirectionalBFS,
+	"jps":           jps,
+}
+
+const defaultPathAlgorithm = "bfs"
+
+func pathAlgorithm(algo string) (PathAlgorithm, string) {
+	if fn, ok := pathAlgorithms[algo]; ok {
+		return fn, algo
+	}
+	return pathAlgorithms[defaultPathAlgorithm], defaultPathAlgorithm
+}
+
 type Item struct {
 	point    Point
 	priority int
@@ -42,81 +209,412 @@ func (pq *PriorityQueue) Pop() any {
 	return item
 }
 
-func heuristic(a, b Point) int {
-	return int(math.Abs(float64(a.X-b.X)) + math.Abs(float64(a.Y-b.Y)))
-}
-
-func astar(maze [][]int, start, end Point) []Point {
-	if maze[start.Y][start.X] == 1 || maze[end.Y][end.X] == 1 {
-		return nil
+// bestFirstSearch is the shared driver behind astar, dijkstra, and
+// greedy best-first: all three pop the lowest-priority open node and
+// relax its neighbours, differing only in how priority is derived from
+// the accumulated cost g and the heuristic h.
+//
+// openIndex tracks each open cell's *Item so relaxing a cell already in
+// the heap calls heap.Fix on its existing entry instead of pushing a
+// second one; without it the open set accumulates stale duplicates that
+// inflate both memory and runtime on large grids. closedSet stops a
+// popped cell from ever being re-expanded, which heap.Fix alone doesn't
+// prevent.
+func bestFirstSearch(ctx context.Context, grid [][]int, start, end Point, movement Movement, priority func(g, h int) int, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	if !passable(grid, start) || !passable(grid, end) {
+		return nil, nil
 	}
 
+	h := heuristicFor(movement)
+
 	openSet := &PriorityQueue{}
 	heap.Init(openSet)
-	heap.Push(openSet, &Item{point: start, priority: 0})
+	startItem := &Item{point: start, priority: priority(0, h(start, end))}
+	heap.Push(openSet, startItem)
+	openIndex := map[Point]*Item{start: startItem}
+	closedSet := make(map[Point]bool)
 
 	cameFrom := make(map[Point]Point)
 	gScore := make(map[Point]int)
 	gScore[start] = 0
-	fScore := make(map[Point]int)
-	fScore[start] = heuristic(start, end)
 
+	var explored []Point
+	step := 0
 	for openSet.Len() > 0 {
+		if err := ctrl.gate(ctx); err != nil {
+			return nil, err
+		}
+
 		current := heap.Pop(openSet).(*Item).point
+		delete(openIndex, current)
+		closedSet[current] = true
+
+		step++
+		explored = append(explored, current)
+		if err := send(ctx, out, AlgorithmStep{Step: step, Data: PathStepData{Kind: "visit", Point: current}}); err != nil {
+			return nil, err
+		}
 
 		if current == end {
-			return reconstructPath(cameFrom, current)
+			path := reconstructPath(cameFrom, current)
+			if err := send(ctx, out, finalStep(&step, path, explored, gScore)); err != nil {
+				return nil, err
+			}
+			return path, nil
 		}
 
-		for _, dir := range [][]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}} {
-			neighbour := Point{current.X + dir[0], current.Y + dir[1]}
-			if neighbour.X < 0 || neighbour.X >= len(maze[0]) || neighbour.Y < 0 || neighbour.Y >= len(maze) || maze[neighbour.Y][neighbour.X] == 1 {
+		for _, nb := range neighbourSteps(grid, current, movement) {
+			if closedSet[nb.point] {
+				continue
+			}
+
+			tentativeGScore := gScore[current] + nb.cost
+			if g, ok := gScore[nb.point]; ok && tentativeGScore >= g {
 				continue
 			}
 
-			tentativeGScore := gScore[current] + 1
-			if g, ok := gScore[neighbour]; !ok || tentativeGScore < g {
-				cameFrom[neighbour] = current
-				gScore[neighbour] = tentativeGScore
-				fScore[neighbour] = tentativeGScore + heuristic(neighbour, end)
-				heap.Push(openSet, &Item{point: neighbour, priority: fScore[neighbour]})
+			cameFrom[nb.point] = current
+			gScore[nb.point] = tentativeGScore
+			newPriority := priority(tentativeGScore, h(nb.point, end))
+
+			if item, ok := openIndex[nb.point]; ok {
+				item.priority = newPriority
+				heap.Fix(openSet, item.index)
+			} else {
+				item := &Item{point: nb.point, priority: newPriority}
+				heap.Push(openSet, item)
+				openIndex[nb.point] = item
 			}
 		}
 	}
 
-	return nil
+	return nil, nil
+}
+
+// astar streams a PathStepData "visit" AlgorithmStep for every node it
+// expands, followed by one "path" step once it reaches end, so a caller
+// can render the search live instead of waiting for the final route.
+// ctrl.gate is checked between expansions so the run can be paused,
+// single-stepped, or cancelled, and ctx.Done() is honoured throughout so
+// a deadline or client disconnect stops the search promptly.
+func astar(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	return bestFirstSearch(ctx, grid, start, end, movement, func(g, h int) int { return g + h }, out, ctrl)
+}
+
+// dijkstra is astar with the heuristic switched off, so it explores by
+// accumulated cost alone.
+func dijkstra(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	return bestFirstSearch(ctx, grid, start, end, movement, func(g, h int) int { return g }, out, ctrl)
 }
 
-func bfs(maze [][]int, start, end Point) []Point {
-	if maze[start.Y][start.X] == 1 || maze[end.Y][end.X] == 1 {
-		return nil
+// greedyBestFirst ignores accumulated cost entirely and always expands
+// whichever open node looks closest to end, which is fast but not
+// guaranteed shortest.
+func greedyBestFirst(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	return bestFirstSearch(ctx, grid, start, end, movement, func(g, h int) int { return h }, out, ctrl)
+}
+
+// bfs mirrors astar's streaming/cancellation behaviour for an unweighted
+// breadth-first search: every step costs the same regardless of terrain,
+// so it ignores neighbourSteps' weighting and just follows movement's
+// connectivity. The queue is a container/list rather than a slice popped
+// with queue[1:], which on a long run kept every dequeued element alive
+// by holding the underlying array's original backing slice.
+func bfs(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	if !passable(grid, start) || !passable(grid, end) {
+		return nil, nil
 	}
 
-	queue := []Point{start}
+	queue := list.New()
+	queue.PushBack(start)
 	cameFrom := make(map[Point]Point)
 	visited := make(map[Point]bool)
 	visited[start] = true
+	gScore := map[Point]int{start: 0}
+
+	var explored []Point
+	step := 0
+	for queue.Len() > 0 {
+		if err := ctrl.gate(ctx); err != nil {
+			return nil, err
+		}
+
+		front := queue.Front()
+		current := queue.Remove(front).(Point)
+		step++
+		explored = append(explored, current)
+		if err := send(ctx, out, AlgorithmStep{Step: step, Data: PathStepData{Kind: "visit", Point: current}}); err != nil {
+			return nil, err
+		}
+
+		if current == end {
+			path := reconstructPath(cameFrom, current)
+			if err := send(ctx, out, finalStep(&step, path, explored, gScore)); err != nil {
+				return nil, err
+			}
+			return path, nil
+		}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+		for _, nb := range neighbourSteps(grid, current, movement) {
+			if visited[nb.point] {
+				continue
+			}
+			visited[nb.point] = true
+			gScore[nb.point] = gScore[current] + 1
+			queue.PushBack(nb.point)
+			cameFrom[nb.point] = current
+		}
+	}
+
+	return nil, nil
+}
+
+// bidirectionalBFS grows unweighted layers out from both start and end
+// at once and stops as soon as the two meet, which visits far fewer
+// nodes than a single-ended BFS on open grids. Like bfs it ignores
+// terrain weight; gScore reports hop count from start.
+func bidirectionalBFS(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	if !passable(grid, start) || !passable(grid, end) {
+		return nil, nil
+	}
+	if start == end {
+		step := 0
+		path := []Point{start}
+		if err := send(ctx, out, finalStep(&step, path, nil, map[Point]int{start: 0})); err != nil {
+			return nil, err
+		}
+		return path, nil
+	}
+
+	cameFromStart := make(map[Point]Point)
+	cameFromEnd := make(map[Point]Point)
+	visitedStart := map[Point]bool{start: true}
+	visitedEnd := map[Point]bool{end: true}
+	frontierStart := []Point{start}
+	frontierEnd := []Point{end}
+
+	var explored []Point
+	step := 0
+
+	expand := func(frontier []Point, visited, otherVisited map[Point]bool, cameFrom map[Point]Point) ([]Point, Point, bool, error) {
+		var next []Point
+		for _, current := range frontier {
+			if err := ctrl.gate(ctx); err != nil {
+				return nil, Point{}, false, err
+			}
+			step++
+			explored = append(explored, current)
+			if err := send(ctx, out, AlgorithmStep{Step: step, Data: PathStepData{Kind: "visit", Point: current}}); err != nil {
+				return nil, Point{}, false, err
+			}
+			if otherVisited[current] {
+				return nil, current, true, nil
+			}
+			for _, nb := range neighbourSteps(grid, current, movement) {
+				if visited[nb.point] {
+					continue
+				}
+				visited[nb.point] = true
+				cameFrom[nb.point] = current
+				next = append(next, nb.point)
+				if otherVisited[nb.point] {
+					return nil, nb.point, true, nil
+				}
+			}
+		}
+		return next, Point{}, false, nil
+	}
+
+	for len(frontierStart) > 0 && len(frontierEnd) > 0 {
+		var meet Point
+		var met bool
+		var err error
+
+		if frontierStart, meet, met, err = expand(frontierStart, visitedStart, visitedEnd, cameFromStart); err != nil {
+			return nil, err
+		}
+		if met {
+			return finishBidirectional(ctx, out, &step, cameFromStart, cameFromEnd, meet, explored)
+		}
+
+		if frontierEnd, meet, met, err = expand(frontierEnd, visitedEnd, visitedStart, cameFromEnd); err != nil {
+			return nil, err
+		}
+		if met {
+			return finishBidirectional(ctx, out, &step, cameFromStart, cameFromEnd, meet, explored)
+		}
+	}
+
+	return nil, nil
+}
+
+func finishBidirectional(ctx context.Context, out chan<- AlgorithmStep, step *int, cameFromStart, cameFromEnd map[Point]Point, meet Point, explored []Point) ([]Point, error) {
+	fromStart := reconstructPath(cameFromStart, meet)
+	fromEnd := reconstructPath(cameFromEnd, meet)
+
+	path := append([]Point{}, fromStart...)
+	for i := len(fromEnd) - 2; i >= 0; i-- {
+		path = append(path, fromEnd[i])
+	}
+
+	gScore := make(map[Point]int, len(fromStart))
+	for i, p := range fromStart {
+		gScore[p] = i
+	}
+
+	if err := send(ctx, out, finalStep(step, path, explored, gScore)); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// jps is Jump Point Search restricted to uniform-cost 8-connected grids:
+// it skips over runs of identical cells between "jump points" (forced
+// turns and the goal; a run that ends in a wall yields no jump point)
+// instead of relaxing every intermediate
+// cell, then expands each cell on the reconstructed path so the returned
+// route still lists every cell like the other algorithms. It does not
+// honour terrain weight - a uniform-cost grid is the contract the
+// technique requires to stay correct.
+func jps(ctx context.Context, grid [][]int, start, end Point, movement Movement, out chan<- AlgorithmStep, ctrl *runControl) ([]Point, error) {
+	if !passable(grid, start) || !passable(grid, end) {
+		return nil, nil
+	}
+
+	h := heuristicFor(Movement8Way)
+
+	openSet := &PriorityQueue{}
+	heap.Init(openSet)
+	heap.Push(openSet, &Item{point: start, priority: 0})
+
+	cameFrom := make(map[Point]Point)
+	gScore := map[Point]int{start: 0}
+
+	var explored []Point
+	step := 0
+	for openSet.Len() > 0 {
+		if err := ctrl.gate(ctx); err != nil {
+			return nil, err
+		}
+
+		current := heap.Pop(openSet).(*Item).point
+		step++
+		explored = append(explored, current)
+		if err := send(ctx, out, AlgorithmStep{Step: step, Data: PathStepData{Kind: "visit", Point: current}}); err != nil {
+			return nil, err
+		}
 
 		if current == end {
-			return reconstructPath(cameFrom, current)
+			path := expandJumpPath(reconstructPath(cameFrom, current))
+			if err := send(ctx, out, finalStep(&step, path, explored, gScore)); err != nil {
+				return nil, err
+			}
+			return path, nil
 		}
 
-		for _, dir := range [][]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}} {
-			neighbour := Point{current.X + dir[0], current.Y + dir[1]}
-			if neighbour.X < 0 || neighbour.X >= len(maze[0]) || neighbour.Y < 0 || neighbour.Y >= len(maze) || maze[neighbour.Y][neighbour.X] == 1 || visited[neighbour] {
+		for _, dir := range directions8 {
+			jp, ok := jump(grid, current, dir, end)
+			if !ok {
 				continue
 			}
-			visited[neighbour] = true
-			queue = append(queue, neighbour)
-			cameFrom[neighbour] = current
+			tentativeGScore := gScore[current] + jumpDistance(current, jp)
+			if g, ok := gScore[jp]; !ok || tentativeGScore < g {
+				cameFrom[jp] = current
+				gScore[jp] = tentativeGScore
+				heap.Push(openSet, &Item{point: jp, priority: tentativeGScore + h(jp, end)})
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// jump walks from current in dir until it reaches the goal or a cell
+// with a forced neighbour (one whose only way around an adjacent wall is
+// through this cell), returning that cell as the next jump point; if it
+// hits a wall first it reports false. Diagonal jumps additionally probe the two straight directions
+// at every step, since a straight jump point reachable from here forces
+// the diagonal to stop as well.
+func jump(grid [][]int, current Point, dir [2]int, end Point) (Point, bool) {
+	next := Point{current.X + dir[0], current.Y + dir[1]}
+	if !passable(grid, next) {
+		return Point{}, false
+	}
+	if next == end {
+		return next, true
+	}
+
+	dx, dy := dir[0], dir[1]
+	switch {
+	case dx != 0 && dy != 0:
+		if (passable(grid, Point{next.X - dx, next.Y + dy}) && !passable(grid, Point{next.X - dx, next.Y})) ||
+			(passable(grid, Point{next.X + dx, next.Y - dy}) && !passable(grid, Point{next.X, next.Y - dy})) {
+			return next, true
+		}
+		if _, ok := jump(grid, next, [2]int{dx, 0}, end); ok {
+			return next, true
+		}
+		if _, ok := jump(grid, next, [2]int{0, dy}, end); ok {
+			return next, true
+		}
+	case dx != 0:
+		if (passable(grid, Point{next.X, next.Y + 1}) && !passable(grid, Point{next.X - dx, next.Y + 1})) ||
+			(passable(grid, Point{next.X, next.Y - 1}) && !passable(grid, Point{next.X - dx, next.Y - 1})) {
+			return next, true
+		}
+	default:
+		if (passable(grid, Point{next.X + 1, next.Y}) && !passable(grid, Point{next.X + 1, next.Y - dy})) ||
+			(passable(grid, Point{next.X - 1, next.Y}) && !passable(grid, Point{next.X - 1, next.Y - dy})) {
+			return next, true
+		}
+	}
+
+	return jump(grid, next, dir, end)
+}
+
+func jumpDistance(a, b Point) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if dx == dy {
+		return diagonalStep * dx
+	}
+	if dy == 0 {
+		return orthogonalStep * dx
+	}
+	return orthogonalStep * dy
+}
+
+// expandJumpPath fills in the cells JPS skipped between consecutive jump
+// points so the response always lists a full, walkable cell-by-cell path.
+func expandJumpPath(jumpPoints []Point) []Point {
+	if len(jumpPoints) == 0 {
+		return jumpPoints
+	}
+	full := []Point{jumpPoints[0]}
+	for i := 1; i < len(jumpPoints); i++ {
+		full = append(full, expandJumpSegment(jumpPoints[i-1], jumpPoints[i])...)
+	}
+	return full
+}
+
+func expandJumpSegment(a, b Point) []Point {
+	step := func(v int) int {
+		if v > 0 {
+			return 1
 		}
+		if v < 0 {
+			return -1
+		}
+		return 0
 	}
+	dx, dy := step(b.X-a.X), step(b.Y-a.Y)
 
-	return nil
+	var segment []Point
+	cur := a
+	for cur != b {
+		cur = Point{cur.X + dx, cur.Y + dy}
+		segment = append(segment, cur)
+	}
+	return segment
 }
 
 func reconstructPath(cameFrom map[Point]Point, current Point) []Point {
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func collectPath(t *testing.T, grid [][]int, start, end Point, algo string, movement Movement) []Point {
+	t.Helper()
+
+	search, _ := pathAlgorithm(algo)
+	out := make(chan AlgorithmStep, 64)
+	ctrl := newRunControl(func() {})
+
+	var path []Point
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(out)
+		path, err = search(context.Background(), grid, start, end, movement, out, ctrl)
+	}()
+	for range out {
+	}
+	<-done
+
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", algo, err)
+	}
+	return path
+}
+
+func TestPathAlgorithmsFindAShortestPathOnAnOpenGrid(t *testing.T) {
+	grid := [][]int{
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	start, end := Point{0, 0}, Point{3, 2}
+
+	for _, algo := range []string{"astar", "dijkstra", "bfs", "bidirectional"} {
+		path := collectPath(t, grid, start, end, algo, Movement4Way)
+		if len(path) == 0 {
+			t.Fatalf("%s: expected a path, got none", algo)
+		}
+		if path[0] != start || path[len(path)-1] != end {
+			t.Fatalf("%s: path %v does not run from %v to %v", algo, path, start, end)
+		}
+		if got, want := len(path)-1, 5; got != want {
+			t.Fatalf("%s: expected a %d-step shortest path, got %d steps (%v)", algo, want, got, path)
+		}
+	}
+}
+
+func TestAstarRoutesAroundExpensiveTerrain(t *testing.T) {
+	// A wall of expensive terrain (cost 20) down the middle column makes
+	// the detour through the gap in row 2 cheaper than crossing directly.
+	grid := [][]int{
+		{0, 20, 0},
+		{0, 20, 0},
+		{0, 0, 0},
+	}
+	path := collectPath(t, grid, Point{0, 0}, Point{2, 0}, "astar", Movement4Way)
+
+	crossesWater := false
+	for _, p := range path {
+		if p.X == 1 && grid[p.Y][p.X] == 20 {
+			crossesWater = true
+		}
+	}
+	if crossesWater {
+		t.Fatalf("expected astar to route around the water column, got %v", path)
+	}
+}
+
+func TestNoCornerCutMovementRefusesDiagonalThroughWallCorner(t *testing.T) {
+	grid := [][]int{
+		{0, 1},
+		{1, 0},
+	}
+	path := collectPath(t, grid, Point{0, 0}, Point{1, 1}, "astar", Movement8WayNoCornerCut)
+	if path != nil {
+		t.Fatalf("expected no path when corner-cutting is disallowed, got %v", path)
+	}
+
+	cornerCut := collectPath(t, grid, Point{0, 0}, Point{1, 1}, "astar", Movement8Way)
+	if len(cornerCut) == 0 {
+		t.Fatalf("expected plain 8way movement to cut the corner, got no path")
+	}
+}
+
+func TestJPSReturnsAFullyConnectedPath(t *testing.T) {
+	grid := [][]int{
+		{0, 0, 0, 0, 0},
+		{0, 1, 1, 1, 0},
+		{0, 0, 0, 0, 0},
+	}
+	path := collectPath(t, grid, Point{0, 0}, Point{4, 0}, "jps", Movement8Way)
+	if len(path) == 0 {
+		t.Fatalf("expected jps to find a path")
+	}
+	for i := 1; i < len(path); i++ {
+		dx, dy := abs(path[i].X-path[i-1].X), abs(path[i].Y-path[i-1].Y)
+		if dx > 1 || dy > 1 {
+			t.Fatalf("path has a gap between %v and %v", path[i-1], path[i])
+		}
+	}
+}
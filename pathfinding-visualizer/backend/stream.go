@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// AlgorithmStep mirrors the shape used by the algo-visualizer backend so
+// the frontend can render both services' streams with the same code.
+type AlgorithmStep struct {
+	Step int         `json:"step"`
+	Data interface{} `json:"data"`
+}
+
+// ControlMessage is sent by the client to steer a running search: over
+// the dedicated control endpoint for SSE runs, or in-band on the socket
+// for WebSocket runs.
+type ControlMessage struct {
+	RunID  string `json:"run_id"`
+	Action string `json:"action"` // "pause", "resume", "step", "cancel"
+}
+
+// runControl coordinates pause/resume/step/cancel signals between a
+// client and the goroutine driving astar/bfs. Searches call gate between
+// node expansions and block there while paused.
+type runControl struct {
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	paused  bool
+	resume  chan struct{}
+	stepped chan struct{}
+}
+
+func newRunControl(cancel context.CancelFunc) *runControl {
+	return &runControl{
+		cancel:  cancel,
+		resume:  make(chan struct{}),
+		stepped: make(chan struct{}, 1),
+	}
+}
+
+func (c *runControl) apply(msg ControlMessage) {
+	switch msg.Action {
+	case "pause":
+		c.mu.Lock()
+		c.paused = true
+		c.mu.Unlock()
+	case "resume":
+		c.mu.Lock()
+		c.paused = false
+		c.mu.Unlock()
+		select {
+		case c.resume <- struct{}{}:
+		default:
+		}
+	case "step":
+		select {
+		case c.stepped <- struct{}{}:
+		default:
+		}
+	case "cancel":
+		c.cancel()
+	}
+}
+
+func (c *runControl) gate(ctx context.Context) error {
+	c.mu.Lock()
+	paused := c.paused
+	c.mu.Unlock()
+	if !paused {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			return nil
+		}
+	}
+	select {
+	case <-c.stepped:
+		return nil
+	case <-c.resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func send(ctx context.Context, out chan<- AlgorithmStep, step AlgorithmStep) error {
+	select {
+	case out <- step:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var runRegistry = struct {
+	mu   sync.Mutex
+	runs map[string]*runControl
+}{runs: make(map[string]*runControl)}
+
+func registerRun(ctrl *runControl) string {
+	id := newRunID()
+	runRegistry.mu.Lock()
+	runRegistry.runs[id] = ctrl
+	runRegistry.mu.Unlock()
+	return id
+}
+
+func unregisterRun(id string) {
+	runRegistry.mu.Lock()
+	delete(runRegistry.runs, id)
+	runRegistry.mu.Unlock()
+}
+
+func lookupRun(id string) (*runControl, bool) {
+	runRegistry.mu.Lock()
+	defer runRegistry.mu.Unlock()
+	ctrl, ok := runRegistry.runs[id]
+	return ctrl, ok
+}
+
+func newRunID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// handleControl lets a client steer an SSE run started on /path by
+// sending {"run_id": "...", "action": "pause|resume|step|cancel"}.
+func handleControl(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var msg ControlMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctrl, ok := lookupRun(msg.RunID)
+	if !ok {
+		http.Error(w, "unknown run_id", http.StatusNotFound)
+		return
+	}
+	ctrl.apply(msg)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deadlineContext honours the optional ?deadline=30s query param: it
+// returns a context cancelled either by the caller or by a
+// time.AfterFunc firing once the deadline elapses, plus a flag reporting
+// which one fired.
+func deadlineContext(parent context.Context, r *http.Request) (ctx context.Context, timedOut func() bool, cancel context.CancelFunc) {
+	ctx, cancel = context.WithCancel(parent)
+	var fired atomic.Bool
+	if d := r.URL.Query().Get("deadline"); d != "" {
+		if dur, err := time.ParseDuration(d); err == nil {
+			timer := time.AfterFunc(dur, func() {
+				fired.Store(true)
+				cancel()
+			})
+			userCancel := cancel
+			cancel = func() {
+				timer.Stop()
+				userCancel()
+			}
+		}
+	}
+	return ctx, fired.Load, cancel
+}
+
+// sseWriter flushes each AlgorithmStep as an SSE "step" frame as soon as
+// it is produced, so the frontend can render arbitrarily large searches
+// without waiting for the whole result.
+func sseWriter(w http.ResponseWriter, runID string, out <-chan AlgorithmStep, done <-chan error, timedOut func() bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprintf(w, "event: run\ndata: {\"run_id\":%q}\n\n", runID)
+	flusher.Flush()
+
+	for {
+		select {
+		case step, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			payload, _ := json.Marshal(step)
+			fmt.Fprintf(w, "event: step\ndata: %s\n\n", payload)
+			flusher.Flush()
+		case err := <-done:
+			if timedOut() {
+				fmt.Fprint(w, "event: timeout\ndata: {\"event\":\"timeout\"}\n\n")
+			} else if err != nil {
+				fmt.Fprintf(w, "event: cancelled\ndata: {\"error\":%q}\n\n", err.Error())
+			} else {
+				fmt.Fprint(w, "event: done\ndata: {}\n\n")
+			}
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsStream upgrades the connection and streams steps as JSON text frames,
+// reading ControlMessages sent back on the same socket in the background.
+func wsStream(w http.ResponseWriter, r *http.Request, ctrl *runControl, out <-chan AlgorithmStep, done <-chan error, timedOut func() bool) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			var msg ControlMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			ctrl.apply(msg)
+		}
+	}()
+
+	for {
+		select {
+		case step, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			if err := conn.WriteJSON(map[string]interface{}{"event": "step", "data": step}); err != nil {
+				ctrl.cancel()
+				return
+			}
+		case err := <-done:
+			if timedOut() {
+				conn.WriteJSON(map[string]string{"event": "timeout"})
+			} else if err != nil {
+				conn.WriteJSON(map[string]string{"event": "cancelled", "error": err.Error()})
+			} else {
+				conn.WriteJSON(map[string]string{"event": "done"})
+			}
+			return
+		}
+	}
+}
+
+func websocketRequested(r *http.Request) bool {
+	return r.Header.Get("Upgrade") == "websocket"
+}